@@ -0,0 +1,103 @@
+//go:build cam
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/liyue201/goqr"
+	"gocv.io/x/gocv"
+)
+
+// runDecodeCam : Webカメラなどのキャプチャデバイスから継続的に映像を取得し、
+// 各フレームをgoqrでQRコードとして認識しながらチャンクを収集する。
+// ingestPayloadを介して、ファイルディレクトリモードと同じPeelingロジックで
+// フレームを取り込み、全ソースチャンクが揃い次第ファイルへ書き出して終了する。
+func runDecodeCam(args []string) error {
+	fs := flag.NewFlagSet("decode-cam", flag.ExitOnError)
+	device := fs.Int("device", 0, "キャプチャデバイス番号(/dev/videoNなど, 省略時は0)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("出力ファイルを指定してください")
+	}
+	outputFile := fs.Arg(0)
+
+	capture, err := gocv.OpenVideoCapture(*device)
+	if err != nil {
+		return fmt.Errorf("キャプチャデバイスのオープン失敗(device=%d): %w", *device, err)
+	}
+	defer capture.Close()
+
+	mat := gocv.NewMat()
+	defer mat.Close()
+
+	decoder := newFountainDecoder()
+
+	for !decoder.done() {
+		if ok := capture.Read(&mat); !ok || mat.Empty() {
+			continue
+		}
+
+		img, err := mat.ToImage()
+		if err != nil {
+			log.Printf("フレームの画像変換に失敗したためスキップします: %v\n", err)
+			continue
+		}
+
+		qrCodes, err := goqr.Recognize(img)
+		if err != nil {
+			continue
+		}
+
+		for _, qr := range qrCodes {
+			if err := decoder.ingestPayload(string(qr.Payload)); err != nil {
+				log.Printf("フレームを無視します: %v\n", err)
+			}
+		}
+
+		printCamProgress(decoder)
+	}
+
+	data, err := decoder.assemble()
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(outputFile, data, 0644); err != nil {
+		return fmt.Errorf("ファイル書き込み失敗: %w", err)
+	}
+
+	fmt.Printf("\nファイルを復元しました: %s\n", outputFile)
+	return nil
+}
+
+// printCamProgress : "received 47/128 chunks (missing: 12, 19, 33...)" のような
+// 進捗行を表示し、オペレーターがGIFを巻き戻すべきタイミングを判断できるようにする。
+func printCamProgress(d *fountainDecoder) {
+	missing := d.missing()
+
+	const maxListed = 10
+	listed := missing
+	truncated := false
+	if len(listed) > maxListed {
+		listed = listed[:maxListed]
+		truncated = true
+	}
+
+	parts := make([]string, len(listed))
+	for i, idx := range listed {
+		parts[i] = fmt.Sprintf("%d", idx)
+	}
+	missingStr := strings.Join(parts, ", ")
+	if truncated {
+		missingStr += ", ..."
+	}
+
+	fmt.Printf("\rreceived %d/%d chunks (missing: %s)          ", d.total-len(missing), d.total, missingStr)
+}
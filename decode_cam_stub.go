@@ -0,0 +1,13 @@
+//go:build !cam
+
+package main
+
+import "fmt"
+
+// runDecodeCam : camビルドタグなしでビルドされた場合のスタブ。
+// gocv/OpenCVへの依存はオプションであり、既定のビルドでは
+// ネイティブライブラリを要求しないよう、実体は別ファイル(decode_cam.go)に
+// 分離している。
+func runDecodeCam(args []string) error {
+	return fmt.Errorf("decode-camは `-tags cam` 付きでビルドした場合のみ利用できます(gocv/OpenCVが必要です)")
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math/rand"
+	"testing"
+)
+
+// TestDecodeFountainFrameRejectsCorruptCRC : ペイロードが1バイトでも化けていれば、
+// decodeFountainFrameがCRC32不一致として拒否することを確認する。
+func TestDecodeFountainFrameRejectsCorruptCRC(t *testing.T) {
+	data := make([]byte, fountainChunkBytes)
+	rand.Read(data)
+	frames := buildFountainFrames(data, 1.0)
+
+	text, err := encodeFountainFrame(frames[0])
+	if err != nil {
+		t.Fatalf("encodeFountainFrame: %v", err)
+	}
+
+	corrupted := tamperPayloadByte(t, text)
+
+	if _, err := decodeFountainFrame(corrupted); err == nil {
+		t.Error("CRC32が不一致のフレームがエラーにならなかった")
+	}
+}
+
+// tamperPayloadByte : エンコード済みフレーム文字列のペイロード部分を1バイト
+// 書き換え、CRC32チェックに引っかかるようにする。
+func tamperPayloadByte(t *testing.T, text string) string {
+	t.Helper()
+
+	raw, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		t.Fatalf("base64デコード失敗: %v", err)
+	}
+	raw[fountainHeaderLen] ^= 0xFF
+
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// TestFountainDecoderRejectsMismatchedFileID : 最初に取り込んだフレームと異なる
+// FileIDのフレームは、addがエラーを返して拒否することを確認する。
+func TestFountainDecoderRejectsMismatchedFileID(t *testing.T) {
+	dataA := make([]byte, fountainChunkBytes)
+	dataB := make([]byte, fountainChunkBytes)
+	rand.Read(dataA)
+	rand.Read(dataB)
+
+	framesA := buildFountainFrames(dataA, 1.0)
+	framesB := buildFountainFrames(dataB, 1.0)
+
+	decoder := newFountainDecoder()
+	if err := decoder.add(framesA[0]); err != nil {
+		t.Fatalf("1件目の取り込みに失敗した: %v", err)
+	}
+	if err := decoder.add(framesB[0]); err == nil {
+		t.Error("異なるFileIDのフレームがエラーにならなかった")
+	}
+}
+
+// TestFountainDecoderDetectsTamperedReassembly : 再構成後のバイト列が壊れていれば、
+// assembleがヘッダ内のSHA-256との不一致として拒否することを確認する。
+func TestFountainDecoderDetectsTamperedReassembly(t *testing.T) {
+	total := 5
+	data := make([]byte, total*fountainChunkBytes)
+	rand.Read(data)
+
+	frames := buildFountainFrames(data, 1.0)
+	decoder := newFountainDecoder()
+	for _, f := range frames {
+		if err := decoder.add(f); err != nil {
+			t.Fatalf("decoder.add: %v", err)
+		}
+	}
+	if !decoder.done() {
+		t.Fatalf("欠損なしで全ソースチャンクが判明していない")
+	}
+
+	// CRC32を個々に通過した正規のチャンクを、再構成後にすり替えて破損を模擬する。
+	decoder.known[0] = bytes.Repeat([]byte{0x00}, fountainChunkBytes)
+
+	if _, err := decoder.assemble(); err == nil {
+		t.Error("再構成後のSHA-256不一致が検出されなかった")
+	}
+}
+
+// TestDecoderSetGroupsByFileIDWithPartialFailure : 複数FileID由来のフレームが
+// 混在していても、decoderSetはFileIDごとに独立したグループとして扱い、
+// 一方が復元不能でも他方の結果は失わずに返すことを確認する。
+func TestDecoderSetGroupsByFileIDWithPartialFailure(t *testing.T) {
+	total := 13
+	dataOK := make([]byte, total*fountainChunkBytes)
+	dataBroken := make([]byte, total*fountainChunkBytes)
+	rand.Read(dataOK)
+	rand.Read(dataBroken)
+
+	framesOK := buildFountainFrames(dataOK, 2.0)
+	framesBroken := buildFountainFrames(dataBroken, 2.0)
+
+	set := newDecoderSet()
+	ingestFrame := func(f fountainFrame) {
+		text, err := encodeFountainFrame(f)
+		if err != nil {
+			t.Fatalf("encodeFountainFrame: %v", err)
+		}
+		if err := set.ingestPayload(text); err != nil {
+			t.Fatalf("ingestPayload: %v", err)
+		}
+	}
+
+	for _, f := range framesOK {
+		ingestFrame(f)
+	}
+	// framesBrokenは、ソースチャンク数に遠く満たない数しか渡さず、
+	// 意図的に復元不能な状態にする。
+	for _, f := range framesBroken[:3] {
+		ingestFrame(f)
+	}
+
+	results, errs := set.assembleAll()
+	if len(errs) == 0 {
+		t.Error("復元不能なグループ分のエラーが返らなかった")
+	}
+
+	var found bool
+	for _, r := range results {
+		if r.fileID == framesOK[0].FileID {
+			found = true
+			if !bytes.Equal(r.data, dataOK) {
+				t.Error("正常なグループの再構成結果がdataOKと一致しない")
+			}
+		}
+		if r.fileID == framesBroken[0].FileID {
+			t.Error("復元不能なはずのグループが結果に含まれている")
+		}
+	}
+	if !found {
+		t.Error("正常なグループの結果が見つからなかった")
+	}
+}
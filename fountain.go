@@ -0,0 +1,632 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"math"
+	"math/bits"
+	"math/rand"
+)
+
+const (
+	// fountainMagic : フレーム形式を識別するためのマジックナンバー("QRTX")。
+	fountainMagic = 0x51525458
+
+	// fountainVersion : フレーム形式のバージョン。
+	fountainVersion = 1
+
+	// fountainChunkBytes : 1ソースチャンクあたりの生データサイズ(バイト)。
+	// QRコードの文字数上限に収まるよう、ヘッダ分を差し引いてもBase64文字列が
+	// 十分小さくなる値を選んでいる。
+	fountainChunkBytes = 400
+
+	// fountainHeaderLen : Payload/CRC32を除いた固定長ヘッダのバイト数。
+	// Magic(4) + Version(1) + FileID(4) + FileSHA256(32) + Total(4) +
+	// ChunkSize(4) + TotalBytes(4) + Index(4) + FrameSeed(4) + PayloadLen(4)
+	fountainHeaderLen = 4 + 1 + 4 + 32 + 4 + 4 + 4 + 4 + 4 + 4
+)
+
+// fountainFrame : 1枚のQRコードに載せる、LT符号(Luby Transform)方式のフレーム。
+// Index < Total のフレームはソースチャンクそのもの(システマティック部分)を運び、
+// Index >= Total のフレームは FrameSeed から疑似乱数的に選ばれたソースチャンク群の
+// XOR合成(Repairフレーム)を運ぶ。受信側は各ソースチャンクの総数1.05倍程度の
+// フレームさえ集まれば、欠落や読み取り失敗があっても元データを再構成できる。
+type fountainFrame struct {
+	Magic      int
+	Version    int
+	FileID     uint32
+	FileSHA256 [32]byte // 元ファイル全体のSHA-256。再構成後の整合性検証に使う
+	Total      int      // ソースチャンクの総数(N)
+	ChunkSize  int      // パディング後の固定チャンク長
+	TotalBytes int      // パディング前の元データ長
+	Index      int      // フレーム番号(0..Total-1: ソース, Total以降: Repair)
+	FrameSeed  uint32
+	Payload    []byte
+	CRC32      uint32
+}
+
+// buildFountainFrames : データをソースチャンクに分割し、redundancy倍の枚数になるよう
+// Repairフレームを追加したフレーム列を返す。redundancyが1.3なら、ソースチャンク数の
+// 30%に相当するRepairフレームが追加で生成される。ただし、LT符号はソースチャンク数K
+// が小さいほど必要な冗長度が相対的に大きくなる(必要な超過枚数はおよそO(√K)で、
+// Kに対する割合としては縮小する)ため、redundancyの指定が不足でPeelingが
+// 収束しない小〜中規模のKに対してはminRepairFramesによる下限を優先する。
+func buildFountainFrames(data []byte, redundancy float64) []fountainFrame {
+	if redundancy < 1.0 {
+		redundancy = 1.0
+	}
+
+	totalBytes := len(data)
+	total := (totalBytes + fountainChunkBytes - 1) / fountainChunkBytes
+	if total == 0 {
+		total = 1
+	}
+
+	// ソースチャンクを固定長にパディングする。末尾の0パディングはTotalBytesを
+	// 元に復元側で切り詰めるため、内容には影響しない。
+	sourceChunks := make([][]byte, total)
+	for i := 0; i < total; i++ {
+		start := i * fountainChunkBytes
+		end := start + fountainChunkBytes
+		if end > totalBytes {
+			end = totalBytes
+		}
+
+		chunk := make([]byte, fountainChunkBytes)
+		copy(chunk, data[start:end])
+		sourceChunks[i] = chunk
+	}
+
+	fileID := rand.Uint32()
+	fileSHA256 := sha256.Sum256(data)
+
+	frames := make([]fountainFrame, 0, int(float64(total)*redundancy))
+	for i, chunk := range sourceChunks {
+		frames = append(frames, fountainFrame{
+			Magic:      fountainMagic,
+			Version:    fountainVersion,
+			FileID:     fileID,
+			FileSHA256: fileSHA256,
+			Total:      total,
+			ChunkSize:  fountainChunkBytes,
+			TotalBytes: totalBytes,
+			Index:      i,
+			Payload:    chunk,
+			CRC32:      crc32.ChecksumIEEE(chunk),
+		})
+	}
+
+	// 小数点以下切り捨てでRepairフレームが0枚になってしまうと、redundancyを
+	// わずかに超えて指定しただけ(例: total=13, redundancy=1.05)でも冗長性が
+	// 一切追加されなくなる。切り上げて必ず指定倍率分のフレームを確保する。
+	numRepair := int(math.Ceil(float64(total)*redundancy)) - total
+	if floor := minRepairFrames(total); numRepair < floor {
+		log.Printf("redundancy=%.2fの指定ではRepairフレームが%d枚だが、Peelingが収束するための下限%d枚まで引き上げます(ソースチャンク数=%d)\n",
+			redundancy, numRepair, floor, total)
+		numRepair = floor
+	}
+	cdf := robustSolitonCDF(total)
+	for r := 0; r < numRepair; r++ {
+		seed := rand.Uint32()
+		indices := fountainSourceIndices(seed, total, cdf)
+
+		combined := make([]byte, fountainChunkBytes)
+		for _, idx := range indices {
+			xorInto(combined, sourceChunks[idx])
+		}
+
+		frames = append(frames, fountainFrame{
+			Magic:      fountainMagic,
+			Version:    fountainVersion,
+			FileID:     fileID,
+			FileSHA256: fileSHA256,
+			Total:      total,
+			ChunkSize:  fountainChunkBytes,
+			TotalBytes: totalBytes,
+			Index:      total + r,
+			FrameSeed:  seed,
+			Payload:    combined,
+			CRC32:      crc32.ChecksumIEEE(combined),
+		})
+	}
+
+	return frames
+}
+
+// fountainSourceIndices : frameSeedから、Repairフレームが合成するソースチャンクの
+// インデックス集合を再現する。エンコード側・デコード側が同じアルゴリズムを使うことで、
+// Repairフレームの構成要素をヘッダのシードだけから復元できる。
+// cdfはrobustSolitonCDFで事前計算した累積分布(totalごとに1回計算すれば十分)。
+func fountainSourceIndices(seed uint32, total int, cdf []float64) []int {
+	rng := rand.New(rand.NewSource(int64(seed)))
+
+	degree := sampleDegree(cdf, rng)
+	if degree > total {
+		degree = total
+	}
+
+	chosen := make(map[int]bool, degree)
+	indices := make([]int, 0, degree)
+	for len(indices) < degree {
+		i := rng.Intn(total)
+		if chosen[i] {
+			continue
+		}
+		chosen[i] = true
+		indices = append(indices, i)
+	}
+	return indices
+}
+
+// minRepairFramesFactor : minRepairFramesの係数。値が大きいほどPeeling/ガウス消去法
+// が収束しやすくなる一方、生成されるフレーム枚数も増える。13チャンク程度の小さな
+// ファイルで2枚欠損・100チャンク程度のファイルで5枚欠損までなら高確率で復元できる
+// よう、実測のシミュレーションに基づいて調整した値。
+const minRepairFramesFactor = 8.0
+
+// minRepairFrames : Peeling(+ガウス消去法によるフォールバック)が実用的な確率で
+// 収束するために必要な、Repairフレーム枚数の下限を返す。LT符号はソースチャンク数K
+// に対してO(√K)の超過枚数を要求することが知られており、redundancyの指定だけでは
+// Kが小さいほど不足しがちになる(例: K=13へredundancy=1.3を指定しても超過は4枚
+// だが、シミュレーション上は2枚の欠損すら半分近くのケースで復元に失敗する)。
+func minRepairFrames(total int) int {
+	return int(math.Ceil(minRepairFramesFactor * math.Sqrt(float64(total))))
+}
+
+// robustSolitonCDF : LT符号(Luby Transform)で標準的に使われるRobust Soliton分布の
+// 累積分布関数を計算する(degree=1..totalのインデックスに対応)。
+// 理想ソリトン分布rho()に、次数1付近の質量を底上げするtau()を加えて正規化したもので、
+// Peelingが序盤から次数1のフレームを十分な頻度で引けるようにし、かつ連鎖的に
+// 他のフレームを解決できる低次数フレームも多く生成されるよう設計されている。
+// c・deltaはLuby(2002)が推奨する典型値を採用した。
+func robustSolitonCDF(total int) []float64 {
+	cdf := make([]float64, total+1) // 1-indexed (cdf[0]は未使用)
+	if total <= 1 {
+		cdf[total] = 1
+		return cdf
+	}
+
+	const (
+		c     = 0.1
+		delta = 0.5
+	)
+	k := float64(total)
+	r := c * math.Log(k/delta) * math.Sqrt(k)
+	if r < 1 {
+		r = 1
+	}
+
+	rho := make([]float64, total+1)
+	rho[1] = 1 / k
+	for i := 2; i <= total; i++ {
+		rho[i] = 1 / (float64(i) * float64(i-1))
+	}
+
+	tau := make([]float64, total+1)
+	threshold := int(k / r)
+	if threshold > total {
+		threshold = total
+	}
+	for i := 1; i < threshold; i++ {
+		tau[i] = r / (float64(i) * k)
+	}
+	if threshold >= 1 {
+		tau[threshold] += r * math.Log(r/delta) / k
+	}
+
+	sum := 0.0
+	for i := 1; i <= total; i++ {
+		sum += rho[i] + tau[i]
+	}
+
+	acc := 0.0
+	for i := 1; i <= total; i++ {
+		acc += (rho[i] + tau[i]) / sum
+		cdf[i] = acc
+	}
+	return cdf
+}
+
+// sampleDegree : 累積分布cdfから、逆関数法で次数を1件サンプリングする。
+func sampleDegree(cdf []float64, rng *rand.Rand) int {
+	x := rng.Float64()
+	for i := 1; i < len(cdf); i++ {
+		if x <= cdf[i] {
+			return i
+		}
+	}
+	return len(cdf) - 1
+}
+
+// xorInto : srcをdstへXORで合成する(dstとsrcは同じ長さであること)
+func xorInto(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// encodeFountainFrame : フレームを固定長ヘッダ+可変長ペイロードのバイナリ形式に
+// 詰め、QRコードの文字列として格納できるようBase64文字列に変換する。
+func encodeFountainFrame(f fountainFrame) (string, error) {
+	raw := make([]byte, 0, fountainHeaderLen+len(f.Payload)+4)
+	raw = binary.BigEndian.AppendUint32(raw, uint32(f.Magic))
+	raw = append(raw, byte(f.Version))
+	raw = binary.BigEndian.AppendUint32(raw, f.FileID)
+	raw = append(raw, f.FileSHA256[:]...)
+	raw = binary.BigEndian.AppendUint32(raw, uint32(f.Total))
+	raw = binary.BigEndian.AppendUint32(raw, uint32(f.ChunkSize))
+	raw = binary.BigEndian.AppendUint32(raw, uint32(f.TotalBytes))
+	raw = binary.BigEndian.AppendUint32(raw, uint32(f.Index))
+	raw = binary.BigEndian.AppendUint32(raw, f.FrameSeed)
+	raw = binary.BigEndian.AppendUint32(raw, uint32(len(f.Payload)))
+	raw = append(raw, f.Payload...)
+	raw = binary.BigEndian.AppendUint32(raw, f.CRC32)
+
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// decodeFountainFrame : QRコードから読み取った文字列をフレームへ復元し、
+// CRC32による整合性チェックを行う。
+func decodeFountainFrame(text string) (fountainFrame, error) {
+	raw, err := base64.StdEncoding.DecodeString(text)
+	if err != nil {
+		return fountainFrame{}, fmt.Errorf("Base64デコード失敗: %w", err)
+	}
+	if len(raw) < fountainHeaderLen+4 {
+		return fountainFrame{}, fmt.Errorf("フレームが短すぎます(%dバイト)", len(raw))
+	}
+
+	var f fountainFrame
+	f.Magic = int(binary.BigEndian.Uint32(raw[0:4]))
+	f.Version = int(raw[4])
+	f.FileID = binary.BigEndian.Uint32(raw[5:9])
+	copy(f.FileSHA256[:], raw[9:41])
+	f.Total = int(binary.BigEndian.Uint32(raw[41:45]))
+	f.ChunkSize = int(binary.BigEndian.Uint32(raw[45:49]))
+	f.TotalBytes = int(binary.BigEndian.Uint32(raw[49:53]))
+	f.Index = int(binary.BigEndian.Uint32(raw[53:57]))
+	f.FrameSeed = binary.BigEndian.Uint32(raw[57:61])
+	payloadLen := int(binary.BigEndian.Uint32(raw[61:65]))
+
+	if len(raw) != fountainHeaderLen+payloadLen+4 {
+		return fountainFrame{}, fmt.Errorf("フレーム長が不正です")
+	}
+	f.Payload = raw[fountainHeaderLen : fountainHeaderLen+payloadLen]
+	f.CRC32 = binary.BigEndian.Uint32(raw[fountainHeaderLen+payloadLen:])
+
+	if f.Magic != fountainMagic {
+		return fountainFrame{}, fmt.Errorf("不明なフレーム形式です(magic不一致)")
+	}
+	if crc32.ChecksumIEEE(f.Payload) != f.CRC32 {
+		return fountainFrame{}, fmt.Errorf("フレームのCRC32が一致しません(破損または読み取り不良)")
+	}
+
+	return f, nil
+}
+
+// fountainPending : まだソースチャンクへ解決できていないフレーム。
+// indicesにはこのフレームが合成するソースチャンクのうち、まだ判明していない
+// インデックスのみが残る。
+type fountainPending struct {
+	indices []int
+	payload []byte
+}
+
+// fountainDecoder : LT符号のBelief Propagation(Peeling)によるソースチャンク復元器。
+// ファイルディレクトリ走査やGIFフレーム走査など、フレームの取得元が異なる
+// デコードモードから共通して利用する。
+type fountainDecoder struct {
+	fileIDSet  bool
+	fileID     uint32
+	fileSHA256 [32]byte
+	total      int
+	chunkSize  int
+	totalBytes int
+	degreeCDF  []float64 // totalが確定した時点で一度だけ計算するRobust Soliton分布
+
+	known   map[int][]byte
+	pending []fountainPending
+}
+
+// newFountainDecoder : 空の状態のデコーダを生成する
+func newFountainDecoder() *fountainDecoder {
+	return &fountainDecoder{known: make(map[int][]byte)}
+}
+
+// add : 1フレーム分のデータを取り込み、判明し次第ソースチャンクを復元していく。
+// 保持しているFileIDと異なるフレームはエラーを返し、呼び出し側でスキップできるようにする。
+func (d *fountainDecoder) add(f fountainFrame) error {
+	if !d.fileIDSet {
+		d.fileID = f.FileID
+		d.fileSHA256 = f.FileSHA256
+		d.total = f.Total
+		d.chunkSize = f.ChunkSize
+		d.totalBytes = f.TotalBytes
+		d.degreeCDF = robustSolitonCDF(f.Total)
+		d.fileIDSet = true
+	} else if f.FileID != d.fileID {
+		return fmt.Errorf("異なるfileIDのフレームです(%d != %d)", f.FileID, d.fileID)
+	} else if f.Total != d.total {
+		return fmt.Errorf("想定しているチャンク総数(%d)と異なる値(%d)を検出", d.total, f.Total)
+	}
+
+	var indices []int
+	if f.Index < f.Total {
+		indices = []int{f.Index}
+	} else {
+		indices = fountainSourceIndices(f.FrameSeed, f.Total, d.degreeCDF)
+	}
+
+	d.reduce(indices, f.Payload)
+	return nil
+}
+
+// reduce : 新しく得られたXOR合成値を、既知のソースチャンクに対して除去しながら、
+// 単一のソースチャンクへ解決できるところまで解決する(Peeling)。
+func (d *fountainDecoder) reduce(indices []int, payload []byte) {
+	remaining := make([]int, 0, len(indices))
+	combined := make([]byte, len(payload))
+	copy(combined, payload)
+
+	for _, idx := range indices {
+		if known, ok := d.known[idx]; ok {
+			xorInto(combined, known)
+			continue
+		}
+		remaining = append(remaining, idx)
+	}
+
+	switch len(remaining) {
+	case 0:
+		// すでに全て既知だった場合、この組み合わせに新しい情報はない。
+		return
+	case 1:
+		d.resolve(remaining[0], combined)
+	default:
+		d.pending = append(d.pending, fountainPending{indices: remaining, payload: combined})
+	}
+}
+
+// resolve : ソースチャンクが1件解決するたびに、保留中のフレームへ伝播させる。
+func (d *fountainDecoder) resolve(idx int, data []byte) {
+	if _, ok := d.known[idx]; ok {
+		return
+	}
+	d.known[idx] = data
+
+	pending := d.pending
+	d.pending = nil
+	for _, p := range pending {
+		d.reduce(p.indices, p.payload)
+	}
+}
+
+// gaussianEliminate : まだ解決できていないpendingフレームの集合を、未知の
+// ソースチャンクを列とするGF(2)上の連立一次方程式とみなし、ガウス消去法で
+// 解けるところまで解く。Peelingは「次数1に剥がせたフレームしか解決できない」
+// 弱点があるが、ガウス消去法まで行えば受信したフレーム集合が線形独立である限り
+// 解けるチャンクを漏れなく解決できる(redundancyが1を僅かに超える程度でも、
+// フレーム同士がたまたま線形独立であれば復元できるようになる)。
+func (d *fountainDecoder) gaussianEliminate() {
+	missing := d.missing()
+	if len(missing) == 0 || len(d.pending) == 0 {
+		return
+	}
+
+	col := make(map[int]int, len(missing))
+	for i, idx := range missing {
+		col[idx] = i
+	}
+
+	words := (len(missing) + 63) / 64
+	rows := make([][]uint64, 0, len(d.pending))
+	payloads := make([][]byte, 0, len(d.pending))
+	for _, p := range d.pending {
+		row := make([]uint64, words)
+		for _, idx := range p.indices {
+			c, ok := col[idx]
+			if !ok {
+				// 解決済みになったインデックスが紛れ込んでいる(あり得る経路だが、
+				// reduceで既知分はXOR済みのはずなので通常は発生しない)。
+				continue
+			}
+			row[c/64] |= 1 << uint(c%64)
+		}
+		payload := make([]byte, len(p.payload))
+		copy(payload, p.payload)
+
+		rows = append(rows, row)
+		payloads = append(payloads, payload)
+	}
+
+	// 簡約行階段形(RREF)まで掃き出す。各列について、その列が立っている行を
+	// ひとつpivotに選び、他の全行からXORで消していく。
+	rank := 0
+	for c := 0; c < len(missing) && rank < len(rows); c++ {
+		pivot := -1
+		for r := rank; r < len(rows); r++ {
+			if rows[r][c/64]>>(uint(c%64))&1 == 1 {
+				pivot = r
+				break
+			}
+		}
+		if pivot == -1 {
+			continue
+		}
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+		payloads[rank], payloads[pivot] = payloads[pivot], payloads[rank]
+
+		for r := 0; r < len(rows); r++ {
+			if r == rank {
+				continue
+			}
+			if rows[r][c/64]>>(uint(c%64))&1 == 1 {
+				xorBits(rows[r], rows[rank])
+				xorInto(payloads[r], payloads[rank])
+			}
+		}
+		rank++
+	}
+
+	// RREF後、他の列を一切持たない行(立っているビットが1本だけの行)は
+	// そのままソースチャンクの値そのものを表す。
+	for r := 0; r < rank; r++ {
+		c, ok := soleBit(rows[r])
+		if !ok {
+			continue
+		}
+		d.resolve(missing[c], payloads[r])
+	}
+}
+
+// xorBits : dstとsrcのビット集合をXORで合成する(dst, srcは同じ長さのワード列)
+func xorBits(dst, src []uint64) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+// soleBit : ビット集合がちょうど1本だけ立っている場合、そのビット位置を返す。
+func soleBit(row []uint64) (int, bool) {
+	pos := -1
+	for i, word := range row {
+		if word == 0 {
+			continue
+		}
+		if word&(word-1) != 0 {
+			return 0, false // 2本以上立っている
+		}
+		if pos != -1 {
+			return 0, false // 既に別のワードでビットが見つかっている
+		}
+		pos = i*64 + bits.TrailingZeros64(word)
+	}
+	if pos == -1 {
+		return 0, false
+	}
+	return pos, true
+}
+
+// ingestPayload : 1件のQRコードペイロード文字列を解析し、デコーダへ取り込む。
+// ファイルディレクトリモードとカメラモードなど、フレームの取得元が異なる
+// デコード処理から共通して呼び出される。
+func (d *fountainDecoder) ingestPayload(text string) error {
+	frame, err := decodeFountainFrame(text)
+	if err != nil {
+		return err
+	}
+	return d.add(frame)
+}
+
+// done : 全ソースチャンクが判明したか
+func (d *fountainDecoder) done() bool {
+	return d.fileIDSet && len(d.known) >= d.total
+}
+
+// missing : まだ判明していないソースチャンクのインデックス一覧
+func (d *fountainDecoder) missing() []int {
+	missing := make([]int, 0)
+	for i := 0; i < d.total; i++ {
+		if _, ok := d.known[i]; !ok {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// assemble : 判明済みの全ソースチャンクを結合し、元のファイル長に切り詰めて返す。
+// Peeling単独では「次数1まで剥がせたフレーム」しか解決できず、redundancyが
+// ギリギリの場合(受信フレーム数がTotalと同程度)は本来解ける連立方程式でも
+// 取りこぼすことがある。最後の手段としてGF(2)上のガウス消去法を試み、
+// Peelingが解決できなかった分も可能な限り救済してから不足判定を行う。
+func (d *fountainDecoder) assemble() ([]byte, error) {
+	if !d.done() {
+		d.gaussianEliminate()
+	}
+	if !d.done() {
+		return nil, fmt.Errorf("ソースチャンクが不足しています(%d/%d取得, 不足: %v)", len(d.known), d.total, d.missing())
+	}
+
+	buf := make([]byte, 0, d.total*d.chunkSize)
+	for i := 0; i < d.total; i++ {
+		buf = append(buf, d.known[i]...)
+	}
+
+	if len(buf) > d.totalBytes {
+		buf = buf[:d.totalBytes]
+	}
+
+	if got := sha256.Sum256(buf); got != d.fileSHA256 {
+		return nil, fmt.Errorf("再構成したファイルのSHA-256が一致しません(チャンクの破損または取り違えの疑いがあります)")
+	}
+
+	return buf, nil
+}
+
+// fountainResult : 1つのFileIDに対応する再構成結果。
+type fountainResult struct {
+	fileID uint32
+	data   []byte
+}
+
+// decoderSet : 同一ディレクトリ/GIFに複数ファイル分のフレームが混在していても、
+// FileIDごとに独立したfountainDecoderへ振り分けて並行に再構成する。
+// ディレクトリに古いPNGが残っていて異なるFileIDのフレームが混ざっても、
+// それぞれ別グループとして扱われるため互いのチャンクを汚染しない。
+type decoderSet struct {
+	decoders map[uint32]*fountainDecoder
+	order    []uint32
+}
+
+// newDecoderSet : 空の状態のデコーダ集合を生成する
+func newDecoderSet() *decoderSet {
+	return &decoderSet{decoders: make(map[uint32]*fountainDecoder)}
+}
+
+// ingestPayload : ペイロードのFileIDに応じたデコーダへフレームを振り分ける。
+func (s *decoderSet) ingestPayload(text string) error {
+	frame, err := decodeFountainFrame(text)
+	if err != nil {
+		return err
+	}
+
+	d, ok := s.decoders[frame.FileID]
+	if !ok {
+		d = newFountainDecoder()
+		s.decoders[frame.FileID] = d
+		s.order = append(s.order, frame.FileID)
+	}
+	return d.add(frame)
+}
+
+// allDone : 取り込んだ全グループが再構成可能な状態になったか
+func (s *decoderSet) allDone() bool {
+	if len(s.decoders) == 0 {
+		return false
+	}
+	for _, d := range s.decoders {
+		if !d.done() {
+			return false
+		}
+	}
+	return true
+}
+
+// assembleAll : グループをFileIDの初出順に再構成する。あるグループの復元に
+// 失敗しても、他の正常なグループの結果までは破棄せずresultsとして返す。
+// 失敗したグループはerrsに集約し、どのグループが原因かを呼び出し側へ伝える。
+func (s *decoderSet) assembleAll() (results []fountainResult, errs []error) {
+	for _, id := range s.order {
+		data, err := s.decoders[id].assemble()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fileID %08x の再構成に失敗しました: %w", id, err))
+			continue
+		}
+		results = append(results, fountainResult{fileID: id, data: data})
+	}
+	return results, errs
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+)
+
+// roundTrip : framesのうちdropCount枚をランダムに間引いた上でデコードし、
+// 復元結果がdataと一致するかどうかを返す。
+func roundTrip(t *testing.T, data []byte, frames []fountainFrame, dropCount int) bool {
+	t.Helper()
+
+	perm := rand.Perm(len(frames))
+	dropped := make(map[int]bool, dropCount)
+	for i := 0; i < dropCount && i < len(perm); i++ {
+		dropped[perm[i]] = true
+	}
+
+	decoder := newFountainDecoder()
+	for i, f := range frames {
+		if dropped[i] {
+			continue
+		}
+		if err := decoder.add(f); err != nil {
+			t.Fatalf("decoder.add: %v", err)
+		}
+	}
+
+	out, err := decoder.assemble()
+	return err == nil && bytes.Equal(out, data)
+}
+
+// TestBuildFountainFramesNoLoss : フレームが1枚も欠けていなければ、
+// redundancyの値によらず必ず復元できることを確認する。
+func TestBuildFountainFramesNoLoss(t *testing.T) {
+	data := make([]byte, 37*fountainChunkBytes+123)
+	rand.Read(data)
+
+	for _, redundancy := range []float64{1.0, 1.05, 1.3, 2.0} {
+		frames := buildFountainFrames(data, redundancy)
+		if !roundTrip(t, data, frames, 0) {
+			t.Errorf("redundancy=%.2f: 欠損なしの復元に失敗した", redundancy)
+		}
+	}
+}
+
+// TestFountainRoundTripToleratesMissingFrames : 欠損耐性がこの機能の核心的な
+// 保証であるため、少数のフレームが失われても高確率で復元できることを
+// 複数トライアルにわたって検証する。redundancy・チャンク数・欠損数の組は、
+// この機能のリクエスト本文(--redundancy 1.3程度の指定で任意の数枚の欠損に
+// 耐える)に沿ったものを使う。
+func TestFountainRoundTripToleratesMissingFrames(t *testing.T) {
+	cases := []struct {
+		name          string
+		totalChunks   int
+		redundancy    float64
+		dropCount     int
+		trials        int
+		minSuccessPct float64
+	}{
+		{"small-file", 13, 1.3, 2, 30, 0.9},
+		{"medium-file", 100, 1.05, 5, 30, 0.9},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data := make([]byte, c.totalChunks*fountainChunkBytes)
+			rand.Read(data)
+
+			success := 0
+			for trial := 0; trial < c.trials; trial++ {
+				frames := buildFountainFrames(data, c.redundancy)
+				if roundTrip(t, data, frames, c.dropCount) {
+					success++
+				}
+			}
+
+			got := float64(success) / float64(c.trials)
+			if got < c.minSuccessPct {
+				t.Errorf("totalChunks=%d redundancy=%.2f drop=%d: 復元成功率%.0f%% (目標%.0f%%以上, %d/%d)",
+					c.totalChunks, c.redundancy, c.dropCount, got*100, c.minSuccessPct*100, success, c.trials)
+			}
+		})
+	}
+}
+
+// TestBuildFountainFramesRespectsMinRepairFrames : redundancyの指定が小さすぎて
+// Repairフレームが実質ゼロ枚になってしまう組み合わせ(かつてのint切り捨てバグ)
+// でも、minRepairFramesの下限により十分な枚数が生成されることを確認する。
+func TestBuildFountainFramesRespectsMinRepairFrames(t *testing.T) {
+	total := 13
+	data := make([]byte, total*fountainChunkBytes)
+	rand.Read(data)
+
+	frames := buildFountainFrames(data, 1.05)
+	numRepair := len(frames) - total
+	if want := minRepairFrames(total); numRepair < want {
+		t.Errorf("numRepair=%d, minRepairFrames(%d)=%d未満になっている", numRepair, total, want)
+	}
+}
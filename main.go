@@ -3,8 +3,12 @@ package main
 import (
 	"bytes"
 	"encoding/base64"
+	"flag"
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
 	_ "image/jpeg"
 	_ "image/png"
 	"log"
@@ -12,57 +16,107 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/liyue201/goqr"
 	qrcode "github.com/skip2/go-qrcode"
 )
 
 const (
-	// 1つのQRコードに格納するBase64文字列の最大長。
-	// 実際にはエラー訂正レベルなどによって格納可能なデータ量が変わるため、
-	// 余裕をもって小さめにしている。
-	chunkSize = 1200
+	// qrImageSize : QRコードを画像化する際のモジュール1つあたりのピクセル数。
+	// 負の値を指定すると、モジュールの境界がぼやけず読み取り精度が安定する
+	// 可変サイズ画像になる(go-qrcodeのImage/Encodeの仕様)。
+	qrImageSize = -6
+
+	// defaultGIFFrameDelay : GIFアニメーションの1フレームあたりのデフォルト表示時間(単位: 1/100秒)。
+	defaultGIFFrameDelay = 50
+
+	// defaultTermFPS : encode-termで1秒あたりに表示するチャンク数のデフォルト値。
+	defaultTermFPS = 2.0
+
+	// defaultRedundancy : Repairフレームを追加しない場合の冗長度(ソースチャンクのみ)。
+	defaultRedundancy = 1.0
+)
+
+// ANSIエスケープシーケンス。encode-termでの半角ブロック描画に使用する。
+const (
+	ansiReset   = "\x1b[0m"
+	ansiClear   = "\x1b[2J\x1b[H"
+	ansiFGBlack = "\x1b[30m"
+	ansiFGWhite = "\x1b[97m"
+	ansiBGBlack = "\x1b[40m"
+	ansiBGWhite = "\x1b[107m"
 )
 
 // usage : 引数の説明を表示する
 func usage() {
 	fmt.Println("Usage:")
-	fmt.Println("  main encode <inputFile> <outputDir>")
+	fmt.Println("  main encode <inputFile> <outputDir> [--redundancy N]")
 	fmt.Println("    -> 指定したファイルをQRコード(複数PNG)に分割して出力します。")
+	fmt.Println("       --redundancy: ソースチャンク数に対する出力フレーム数の倍率")
+	fmt.Println("       (例: 1.3で30%分のRepairフレームを追加生成。省略時は1.0。")
+	fmt.Println("       ソースチャンク数が少ないファイルでは、Peelingが収束するために")
+	fmt.Println("       必要な下限までRepairフレームが自動的に増やされます。実際の")
+	fmt.Println("       生成枚数は実行時のログと最終的な枚数表示で確認してください)")
 	fmt.Println()
-	fmt.Println("  main decode <inputDir> <outputFile>")
+	fmt.Println("  main decode <inputDir> <outputFile> [--legacy]")
 	fmt.Println("    -> 指定したディレクトリにあるPNGファイルをすべて読み込みQRコードを解析し、")
+	fmt.Println("       復元したバイナリを指定ファイルに書き出します。ソースチャンクの")
+	fmt.Println("       約1.05倍のフレームが揃っていれば、一部が欠落・破損していても復元できます。")
+	fmt.Println("       ディレクトリ内に異なるファイル由来のQRコードが混在している場合、")
+	fmt.Println("       FileIDごとにグループ分けしてそれぞれ出力します(出力ファイル名に")
+	fmt.Println("       FileIDを付与)。再構成後はヘッダのSHA-256と照合し、一致しなければ")
+	fmt.Println("       書き出しを中止します。")
+	fmt.Println("       --legacy: chunk0-3より前の素朴な\"index/total:chunkData\"形式を読み込む")
+	fmt.Println()
+	fmt.Println("  main encode-gif <inputFile> <outputFile> [--delay N] [--redundancy N]")
+	fmt.Println("    -> 指定したファイルをQRコードに分割し、各フレームを1コマとする")
+	fmt.Println("       アニメーションGIFを出力します。--delayは1コマの表示時間")
+	fmt.Println("       (単位: 1/100秒, 省略時は50)です。")
+	fmt.Println()
+	fmt.Println("  main decode-gif <inputFile> <outputFile>")
+	fmt.Println("    -> encode-gifで生成したアニメーションGIFの各フレームを解析し、")
 	fmt.Println("       復元したバイナリを指定ファイルに書き出します。")
+	fmt.Println()
+	fmt.Println("  main encode-term <inputFile> [--fps N] [--loop] [--invert] [--redundancy N]")
+	fmt.Println("    -> 指定したファイルをQRコードに分割し、PNGを書き出す代わりに")
+	fmt.Println("       各フレームをANSI半角ブロック文字で端末へ直接描画します。")
+	fmt.Println("       --fps: 1秒あたりに表示するフレーム数(省略時は2)")
+	fmt.Println("       --loop: 全フレーム表示後に最初から繰り返す")
+	fmt.Println("       --invert: ダークモード端末向けに白黒を反転する")
+	fmt.Println()
+	fmt.Println("  main decode-cam <outputFile> [--device N]")
+	fmt.Println("    -> Webカメラなどのキャプチャデバイスから継続的に映像を取得し、")
+	fmt.Println("       QRコードを認識しながらチャンクを収集します。全ソースチャンクが")
+	fmt.Println("       揃い次第、復元したバイナリを指定ファイルに書き出します。")
+	fmt.Println("       --device: キャプチャデバイス番号(省略時は0)")
+	fmt.Println("       ビルドタグ'cam'を付けてビルドした場合のみ利用できます。")
 }
 
-// encodeFile : ファイルを読み込み、Base64エンコードして複数のQRコードに変換
-func encodeFile(inputFile, outputDir string) error {
+// encodeFile : ファイルを読み込み、フォンテン符号のフレーム列に変換して複数のQRコードに変換する
+func encodeFile(inputFile, outputDir string, redundancy float64) error {
 	// ファイル読み込み
 	data, err := os.ReadFile(inputFile)
 	if err != nil {
 		return fmt.Errorf("ファイル読み込み失敗: %w", err)
 	}
 
-	// Base64エンコード
-	encoded := base64.StdEncoding.EncodeToString(data)
-
-	// チャンク分割
-	chunks := splitIntoChunks(encoded, chunkSize)
-	totalChunks := len(chunks)
+	frames := buildFountainFrames(data, redundancy)
 
 	// 出力先ディレクトリが無い場合は作成
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("出力ディレクトリ作成失敗: %w", err)
 	}
 
-	// 各チャンクをQRコードに変換してPNG保存
-	for i, chunk := range chunks {
-		// 格納する文字列の形式:
-		// "index/totalChunks:chunkData"
-		content := fmt.Sprintf("%d/%d:%s", i, totalChunks, chunk)
+	// 各フレームをQRコードに変換してPNG保存
+	for i, frame := range frames {
+		content, err := encodeFountainFrame(frame)
+		if err != nil {
+			return err
+		}
 
 		// QRコード生成 (エラー訂正レベルなどは任意に設定可能)
-		pngData, err := qrcode.Encode(content, qrcode.Medium, 256)
+		pngData, err := qrcode.Encode(content, qrcode.Medium, qrImageSize)
 		if err != nil {
 			return fmt.Errorf("QRコード生成失敗: %w", err)
 		}
@@ -74,22 +128,21 @@ func encodeFile(inputFile, outputDir string) error {
 		}
 	}
 
-	fmt.Printf("合計 %d 個のQRコードを生成しました。出力ディレクトリ: %s\n", totalChunks, outputDir)
+	fmt.Printf("合計 %d 個のQRコード(うちRepairフレーム %d 個)を生成しました。出力ディレクトリ: %s\n",
+		len(frames), len(frames)-frames[0].Total, outputDir)
 	return nil
 }
 
-// decodeQRCodes : ディレクトリ内のQRコード(PNG)を解析してBase64文字列を再構成
-func decodeQRCodes(inputDir string) (string, error) {
+// walkQRPayloads : ディレクトリ内のPNGファイルを走査し、認識できた各QRコードの
+// ペイロード文字列をonPayloadへ渡す。新旧どちらのフレーム形式の解析でも使う
+// ファイル探索・画像デコードの共通処理。
+func walkQRPayloads(inputDir string, onPayload func(path, payload string)) error {
 	// ディレクトリ内のPNGファイル一覧を取得
 	files, err := os.ReadDir(inputDir)
 	if err != nil {
-		return "", fmt.Errorf("ディレクトリ読み込み失敗: %w", err)
+		return fmt.Errorf("ディレクトリ読み込み失敗: %w", err)
 	}
 
-	// チャンクを格納するためのマップ (index -> chunkData)
-	chunksMap := make(map[int]string)
-	totalChunks := -1
-
 	for _, f := range files {
 		if f.IsDir() {
 			continue
@@ -103,68 +156,123 @@ func decodeQRCodes(inputDir string) (string, error) {
 		path := filepath.Join(inputDir, f.Name())
 		fileData, err := os.ReadFile(path)
 		if err != nil {
-			return "", fmt.Errorf("ファイル読み込み失敗(%s): %w", path, err)
+			return fmt.Errorf("ファイル読み込み失敗(%s): %w", path, err)
 		}
 
-		// []byte を image.Image にデコード
+		// []byte を image.Image にデコード。破損・途中書き込みのPNGが1枚
+		// 混ざっているだけで走査全体を諦めると、フォンテン符号の欠損耐性が
+		// 意味をなさなくなるため、QRコード解析失敗時と同様にスキップして続行する。
 		img, _, err := image.Decode(bytes.NewReader(fileData))
 		if err != nil {
-			return "", fmt.Errorf("画像デコード失敗(%s): %w", path, err)
+			log.Printf("画像デコードに失敗したためスキップします(%s): %v\n", path, err)
+			continue
 		}
 
 		// QRコード解析
 		qrCodes, err := goqr.Recognize(img)
 		if err != nil {
-			return "", fmt.Errorf("QRコード解析失敗(%s): %w", path, err)
+			log.Printf("QRコード解析に失敗したためスキップします(%s): %v\n", path, err)
+			continue
 		}
 
 		// 通常は1枚のQRコードデータが含まれている想定(複数含まれる場合もあるため考慮)
 		for _, qr := range qrCodes {
-			text := string(qr.Payload)
-			// "index/total:chunkData" の形式を想定
-			parts := strings.SplitN(text, ":", 2)
-			if len(parts) != 2 {
-				log.Printf("予期しないQRコードデータ形式: %s\n", text)
-				continue
-			}
+			onPayload(path, string(qr.Payload))
+		}
+	}
 
-			meta := parts[0]      // "index/total"
-			chunkData := parts[1] // base64チャンク
+	return nil
+}
 
-			metaParts := strings.SplitN(meta, "/", 2)
-			if len(metaParts) != 2 {
-				log.Printf("メタ情報の形式が不正: %s\n", meta)
-				continue
-			}
+// decodeQRCodes : ディレクトリ内のQRコード(PNG)を解析し、フォンテン符号のPeelingで
+// 元データを再構成する。異なるFileID由来のQRコードが混在していても、
+// decoderSetがグループごとに独立して再構成する。
+func decodeQRCodes(inputDir string) ([]fountainResult, error) {
+	set := newDecoderSet()
 
-			indexStr := metaParts[0]
-			totalStr := metaParts[1]
+	err := walkQRPayloads(inputDir, func(path, payload string) {
+		if err := set.ingestPayload(payload); err != nil {
+			log.Printf("フレームを無視します(%s): %v\n", path, err)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			idx, err := strconv.Atoi(indexStr)
-			if err != nil {
-				log.Printf("indexが数値でない: %s\n", indexStr)
-				continue
-			}
+	return assembleDecoderSet(set)
+}
 
-			tChunks, err := strconv.Atoi(totalStr)
-			if err != nil {
-				log.Printf("totalChunksが数値でない: %s\n", totalStr)
-				continue
-			}
+// assembleDecoderSet : decoderSetの再構成結果を取り出す。一部のグループが
+// 復元に失敗しても、成功した他のグループの結果は失わずに返す。
+// 1件も復元できなかった場合のみエラーを返す。
+func assembleDecoderSet(set *decoderSet) ([]fountainResult, error) {
+	results, errs := set.assembleAll()
+	for _, err := range errs {
+		log.Printf("%v\n", err)
+	}
 
-			// 最初に見つかった totalChunks が正と仮定し、他のQRで異なる値があれば警告
-			if totalChunks == -1 {
-				totalChunks = tChunks
-			} else if totalChunks != tChunks {
-				log.Printf("想定しているチャンク総数(%d)と異なる値(%d)を検出\n", totalChunks, tChunks)
-			}
+	if len(results) == 0 {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, fmt.Errorf("QRコードから有効なフレームが取得できませんでした")
+	}
+
+	return results, nil
+}
+
+// decodeLegacyQRCodes : chunk0-3より前に使われていた、素朴な"index/total:chunkData"
+// (base64エンコード済みの全ファイルを単純分割しただけの形式)のQRコードを解析する。
+// --legacy指定時のみ使用する後方互換モードで、FileIDによるグループ分けや
+// SHA-256による整合性検証など新形式の機能はサポートしない。
+func decodeLegacyQRCodes(inputDir string) ([]byte, error) {
+	chunksMap := make(map[int]string)
+	totalChunks := -1
+
+	err := walkQRPayloads(inputDir, func(path, text string) {
+		// "index/total:chunkData" の形式を想定
+		parts := strings.SplitN(text, ":", 2)
+		if len(parts) != 2 {
+			log.Printf("予期しないQRコードデータ形式: %s\n", text)
+			return
+		}
+
+		meta := parts[0]
+		chunkData := parts[1]
+
+		metaParts := strings.SplitN(meta, "/", 2)
+		if len(metaParts) != 2 {
+			log.Printf("メタ情報の形式が不正: %s\n", meta)
+			return
+		}
+
+		idx, err := strconv.Atoi(metaParts[0])
+		if err != nil {
+			log.Printf("indexが数値でない: %s\n", metaParts[0])
+			return
+		}
 
-			chunksMap[idx] = chunkData
+		tChunks, err := strconv.Atoi(metaParts[1])
+		if err != nil {
+			log.Printf("totalChunksが数値でない: %s\n", metaParts[1])
+			return
 		}
+
+		// 最初に見つかった totalChunks が正と仮定し、他のQRで異なる値があれば警告
+		if totalChunks == -1 {
+			totalChunks = tChunks
+		} else if totalChunks != tChunks {
+			log.Printf("想定しているチャンク総数(%d)と異なる値(%d)を検出\n", totalChunks, tChunks)
+		}
+
+		chunksMap[idx] = chunkData
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if totalChunks <= 0 {
-		return "", fmt.Errorf("QRコードから総チャンク数が取得できませんでした")
+		return nil, fmt.Errorf("QRコードから総チャンク数が取得できませんでした")
 	}
 
 	// 0 から totalChunks-1 まで順番に再連結
@@ -172,46 +280,277 @@ func decodeQRCodes(inputDir string) (string, error) {
 	for i := 0; i < totalChunks; i++ {
 		chunk, ok := chunksMap[i]
 		if !ok {
-			return "", fmt.Errorf("チャンク %d が見つかりません", i)
+			return nil, fmt.Errorf("チャンク %d が見つかりません", i)
 		}
 		builder.WriteString(chunk)
 	}
 
-	return builder.String(), nil
+	decoded, err := base64.StdEncoding.DecodeString(builder.String())
+	if err != nil {
+		return nil, fmt.Errorf("Base64デコード失敗: %w", err)
+	}
+	return decoded, nil
+}
+
+// fountainOutputPath : 同一ディレクトリ/GIFから複数ファイル分のグループが復元された
+// 場合に、出力先が衝突しないようFileIDを拡張子の前に挿入したパスを返す。
+// グループが1つだけならoutputFileをそのまま使う。
+func fountainOutputPath(outputFile string, fileID uint32, multiple bool) string {
+	if !multiple {
+		return outputFile
+	}
+	ext := filepath.Ext(outputFile)
+	base := strings.TrimSuffix(outputFile, ext)
+	return fmt.Sprintf("%s.%08x%s", base, fileID, ext)
+}
+
+// decodeFile : QRコード群からファイルを復元する。legacyがtrueの場合は
+// chunk0-3より前の素朴な形式として読み込む。
+func decodeFile(inputDir, outputFile string, legacy bool) error {
+	if legacy {
+		decoded, err := decodeLegacyQRCodes(inputDir)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(outputFile, decoded, 0644); err != nil {
+			return fmt.Errorf("ファイル書き込み失敗: %w", err)
+		}
+		fmt.Printf("ファイルを復元しました: %s\n", outputFile)
+		return nil
+	}
+
+	results, err := decodeQRCodes(inputDir)
+	if err != nil {
+		return err
+	}
+
+	multiple := len(results) > 1
+	for _, r := range results {
+		path := fountainOutputPath(outputFile, r.fileID, multiple)
+		if err := os.WriteFile(path, r.data, 0644); err != nil {
+			return fmt.Errorf("ファイル書き込み失敗: %w", err)
+		}
+		fmt.Printf("ファイルを復元しました: %s\n", path)
+	}
+	return nil
+}
+
+// encodeGIF : ファイルを読み込み、各チャンクのQRコードを1フレームとするアニメーションGIFを出力する。
+// 送信側は生成したGIFを1枚の画面で再生するだけでよく、受信側はスマートフォンのカメラで
+// それを撮影し続ければよいため、大量のPNGファイルをやり取りする必要がなくなる。
+func encodeGIF(inputFile, outputFile string, frameDelay int, redundancy float64) error {
+	// ファイル読み込み
+	data, err := os.ReadFile(inputFile)
+	if err != nil {
+		return fmt.Errorf("ファイル読み込み失敗: %w", err)
+	}
+
+	frames := buildFountainFrames(data, redundancy)
+
+	anim := &gif.GIF{}
+	for _, frame := range frames {
+		content, err := encodeFountainFrame(frame)
+		if err != nil {
+			return err
+		}
+
+		qr, err := qrcode.New(content, qrcode.Medium)
+		if err != nil {
+			return fmt.Errorf("QRコード生成失敗: %w", err)
+		}
+
+		anim.Image = append(anim.Image, qrToPaletted(qr))
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("出力ファイル作成失敗: %w", err)
+	}
+	defer f.Close()
+
+	if err := gif.EncodeAll(f, anim); err != nil {
+		return fmt.Errorf("GIFエンコード失敗: %w", err)
+	}
+
+	fmt.Printf("合計 %d 個のQRコードを%dフレームのGIFとして出力しました: %s\n", len(frames), len(frames), outputFile)
+	return nil
+}
+
+// qrToPaletted : QRコードを、GIFフレームとして扱える白黒2色のパレット画像に変換する
+func qrToPaletted(qr *qrcode.QRCode) *image.Paletted {
+	img := qr.Image(qrImageSize)
+	bounds := img.Bounds()
+
+	paletted := image.NewPaletted(bounds, color.Palette{color.White, color.Black})
+	draw.Draw(paletted, bounds, img, bounds.Min, draw.Src)
+	return paletted
 }
 
-// decodeFile : QRコード群からファイルを復元
-func decodeFile(inputDir, outputFile string) error {
-	// ディレクトリ内のQRコードを解析してBase64文字列を構築
-	base64Data, err := decodeQRCodes(inputDir)
+// decodeGIFChunks : アニメーションGIFの各フレームをQRコードとして解析し、
+// フォンテン符号のPeelingで元データを再構成する。異なるFileID由来のフレームが
+// 混在していても、decoderSetがグループごとに独立して再構成する。
+func decodeGIFChunks(inputFile string) ([]fountainResult, error) {
+	f, err := os.Open(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル読み込み失敗: %w", err)
+	}
+	defer f.Close()
+
+	anim, err := gif.DecodeAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("GIFデコード失敗: %w", err)
+	}
+
+	set := newDecoderSet()
+
+	for i, frame := range anim.Image {
+		if set.allDone() {
+			break
+		}
+
+		// QRコード解析。ブレやコマ落ちで読み取れないフレームは単純にスキップして続行する。
+		qrCodes, err := goqr.Recognize(frame)
+		if err != nil {
+			log.Printf("フレーム%d: QRコード解析に失敗したためスキップします: %v\n", i, err)
+			continue
+		}
+
+		for _, qr := range qrCodes {
+			if err := set.ingestPayload(string(qr.Payload)); err != nil {
+				log.Printf("フレーム%d: フレームを無視します: %v\n", i, err)
+			}
+		}
+	}
+
+	return assembleDecoderSet(set)
+}
+
+// decodeGIFFile : アニメーションGIFからファイルを復元する
+func decodeGIFFile(inputFile, outputFile string) error {
+	results, err := decodeGIFChunks(inputFile)
 	if err != nil {
 		return err
 	}
 
-	// Base64文字列をデコードしてバイナリに戻す
-	decoded, err := base64.StdEncoding.DecodeString(base64Data)
+	multiple := len(results) > 1
+	for _, r := range results {
+		path := fountainOutputPath(outputFile, r.fileID, multiple)
+		if err := os.WriteFile(path, r.data, 0644); err != nil {
+			return fmt.Errorf("ファイル書き込み失敗: %w", err)
+		}
+		fmt.Printf("ファイルを復元しました: %s\n", path)
+	}
+	return nil
+}
+
+// encodeTerm : ファイルをQRコードに分割し、PNGなどのファイルを一切生成せずに
+// 各チャンクをANSI半角ブロック文字で端末へ直接描画する。
+// エアギャップ環境で端末しか使えない場合でも、スマートフォンのカメラへ順番に
+// チャンクを"フラッシュ"して送信できる。
+func encodeTerm(inputFile string, fps float64, loop bool, invert bool, redundancy float64) error {
+	// ファイル読み込み
+	data, err := os.ReadFile(inputFile)
 	if err != nil {
-		return fmt.Errorf("Base64デコード失敗: %w", err)
+		return fmt.Errorf("ファイル読み込み失敗: %w", err)
+	}
+
+	fountainFrames := buildFountainFrames(data, redundancy)
+	totalFrames := len(fountainFrames)
+
+	// あらかじめ全フレームを描画しておき、表示中の生成遅延でフレーム間隔が
+	// 乱れないようにする。
+	frames := make([]string, totalFrames)
+	for i, fr := range fountainFrames {
+		content, err := encodeFountainFrame(fr)
+		if err != nil {
+			return err
+		}
+
+		qr, err := qrcode.New(content, qrcode.Medium)
+		if err != nil {
+			return fmt.Errorf("QRコード生成失敗: %w", err)
+		}
+
+		frames[i] = renderQRTerminal(qr, invert)
 	}
 
-	// ファイルに書き出し
-	if err := os.WriteFile(outputFile, decoded, 0644); err != nil {
-		return fmt.Errorf("ファイル書き込み失敗: %w", err)
+	if fps <= 0 {
+		return fmt.Errorf("fpsは正の値を指定してください: %v", fps)
+	}
+	delay := time.Duration(float64(time.Second) / fps)
+
+	for {
+		for i, frame := range frames {
+			fmt.Print(ansiClear)
+			fmt.Printf("frame %d/%d\n", i+1, totalFrames)
+			fmt.Print(frame)
+			time.Sleep(delay)
+		}
+		if !loop {
+			break
+		}
 	}
 
-	fmt.Printf("ファイルを復元しました: %s\n", outputFile)
 	return nil
 }
 
-// splitIntoChunks : 文字列を指定したサイズに分割する
-func splitIntoChunks(s string, size int) []string {
-	var chunks []string
-	for len(s) > size {
-		chunks = append(chunks, s[:size])
-		s = s[size:]
+// renderQRTerminal : QRコードのビットマップを、1文字で2モジュール行を表現する
+// ANSI半角ブロック文字列に変換する。
+// Bitmap()が返す配列にはクワイエットゾーン(余白)が含まれているため、
+// そのまま描画すればQRコードリーダーが認識できる余白付きの画像になる。
+// invertがtrueの場合はダークモード端末向けに白黒の配色を反転する。
+func renderQRTerminal(qr *qrcode.QRCode, invert bool) string {
+	bitmap := qr.Bitmap()
+
+	darkFG, lightFG := ansiFGBlack, ansiFGWhite
+	darkBG, lightBG := ansiBGBlack, ansiBGWhite
+	if invert {
+		darkFG, lightFG = lightFG, darkFG
+		darkBG, lightBG = lightBG, darkBG
+	}
+
+	height := len(bitmap)
+	width := 0
+	if height > 0 {
+		width = len(bitmap[0])
+	}
+
+	var b strings.Builder
+	for y := 0; y < height; y += 2 {
+		for x := 0; x < width; x++ {
+			upper := bitmap[y][x]
+			lower := false
+			if y+1 < height {
+				lower = bitmap[y+1][x]
+			}
+
+			switch {
+			case upper && lower:
+				// 上下ともに暗モジュール: 全面ブロック
+				b.WriteString(darkFG)
+				b.WriteString("█")
+			case !upper && !lower:
+				// 上下ともに明モジュール: 空白
+				b.WriteString(lightFG)
+				b.WriteString(" ")
+			case upper && !lower:
+				// 上だけ暗モジュール: 上半分ブロック
+				b.WriteString(darkFG)
+				b.WriteString(lightBG)
+				b.WriteString("▀")
+			default:
+				// 下だけ暗モジュール: 下半分ブロック
+				b.WriteString(darkFG)
+				b.WriteString(lightBG)
+				b.WriteString("▄")
+			}
+			b.WriteString(ansiReset)
+		}
+		b.WriteString("\n")
 	}
-	chunks = append(chunks, s)
-	return chunks
+
+	return b.String()
 }
 
 func main() {
@@ -224,29 +563,106 @@ func main() {
 
 	switch command {
 	case "encode":
-		if len(os.Args) != 4 {
+		if len(os.Args) < 4 {
 			fmt.Println("引数が足りません。")
 			usage()
 			return
 		}
 		inputFile := os.Args[2]
 		outputDir := os.Args[3]
-		if err := encodeFile(inputFile, outputDir); err != nil {
+
+		fs := flag.NewFlagSet("encode", flag.ExitOnError)
+		redundancy := fs.Float64("redundancy", defaultRedundancy, "ソースチャンク数に対する出力フレーム数の倍率")
+		if err := fs.Parse(os.Args[4:]); err != nil {
+			log.Fatalf("引数解析失敗: %v", err)
+		}
+
+		if err := encodeFile(inputFile, outputDir, *redundancy); err != nil {
 			log.Fatalf("encode失敗: %v", err)
 		}
 
 	case "decode":
-		if len(os.Args) != 4 {
+		if len(os.Args) < 4 {
 			fmt.Println("引数が足りません。")
 			usage()
 			return
 		}
 		inputDir := os.Args[2]
 		outputFile := os.Args[3]
-		if err := decodeFile(inputDir, outputFile); err != nil {
+
+		fs := flag.NewFlagSet("decode", flag.ExitOnError)
+		legacy := fs.Bool("legacy", false, "chunk0-3より前の\"index/total:chunkData\"形式を読み込む")
+		if err := fs.Parse(os.Args[4:]); err != nil {
+			log.Fatalf("引数解析失敗: %v", err)
+		}
+
+		if err := decodeFile(inputDir, outputFile, *legacy); err != nil {
 			log.Fatalf("decode失敗: %v", err)
 		}
 
+	case "encode-gif":
+		if len(os.Args) < 4 {
+			fmt.Println("引数が足りません。")
+			usage()
+			return
+		}
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+
+		fs := flag.NewFlagSet("encode-gif", flag.ExitOnError)
+		frameDelay := fs.Int("delay", defaultGIFFrameDelay, "1フレームの表示時間(単位: 1/100秒)")
+		redundancy := fs.Float64("redundancy", defaultRedundancy, "ソースチャンク数に対する出力フレーム数の倍率")
+		if err := fs.Parse(os.Args[4:]); err != nil {
+			log.Fatalf("引数解析失敗: %v", err)
+		}
+
+		if err := encodeGIF(inputFile, outputFile, *frameDelay, *redundancy); err != nil {
+			log.Fatalf("encode-gif失敗: %v", err)
+		}
+
+	case "decode-gif":
+		if len(os.Args) != 4 {
+			fmt.Println("引数が足りません。")
+			usage()
+			return
+		}
+		inputFile := os.Args[2]
+		outputFile := os.Args[3]
+		if err := decodeGIFFile(inputFile, outputFile); err != nil {
+			log.Fatalf("decode-gif失敗: %v", err)
+		}
+
+	case "encode-term":
+		if len(os.Args) < 3 {
+			fmt.Println("引数が足りません。")
+			usage()
+			return
+		}
+		inputFile := os.Args[2]
+
+		fs := flag.NewFlagSet("encode-term", flag.ExitOnError)
+		fps := fs.Float64("fps", defaultTermFPS, "1秒あたりに表示するフレーム数")
+		loop := fs.Bool("loop", false, "全フレームを表示し終えたら最初から繰り返す")
+		invert := fs.Bool("invert", false, "ダークモード端末向けに白黒を反転する")
+		redundancy := fs.Float64("redundancy", defaultRedundancy, "ソースチャンク数に対する出力フレーム数の倍率")
+		if err := fs.Parse(os.Args[3:]); err != nil {
+			log.Fatalf("引数解析失敗: %v", err)
+		}
+
+		if err := encodeTerm(inputFile, *fps, *loop, *invert, *redundancy); err != nil {
+			log.Fatalf("encode-term失敗: %v", err)
+		}
+
+	case "decode-cam":
+		if len(os.Args) < 3 {
+			fmt.Println("引数が足りません。")
+			usage()
+			return
+		}
+		if err := runDecodeCam(os.Args[2:]); err != nil {
+			log.Fatalf("decode-cam失敗: %v", err)
+		}
+
 	default:
 		usage()
 	}